@@ -0,0 +1,242 @@
+package tunnel
+
+import (
+	"context"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+
+	"github.com/containers/libpod/pkg/bindings/containers"
+	"github.com/containers/libpod/pkg/domain/entities"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// errDetached is returned internally by detachKeysReader once the
+// configured detach key sequence has been seen on stdin.
+var errDetached = errors.New("detached from container")
+
+// detachKeysReader wraps an input stream and stops forwarding input (by
+// returning errDetached) once the given key sequence has been typed,
+// without forwarding the keys of that sequence downstream.
+type detachKeysReader struct {
+	r       io.Reader
+	keys    []byte
+	matched int
+}
+
+func newDetachKeysReader(r io.Reader, keys []byte) io.Reader {
+	if len(keys) == 0 {
+		return r
+	}
+	return &detachKeysReader{r: r, keys: keys}
+}
+
+func (d *detachKeysReader) Read(p []byte) (int, error) {
+	n, err := d.r.Read(p)
+	for i := 0; i < n; i++ {
+		if p[i] == d.keys[d.matched] {
+			d.matched++
+			if d.matched == len(d.keys) {
+				return i - d.matched + 1, errDetached
+			}
+			continue
+		}
+		d.matched = 0
+	}
+	if err != nil {
+		return n, err
+	}
+	return n, nil
+}
+
+// streamHijackedSession demuxes a hijacked connection into streams.OutputStream
+// and streams.ErrorStream (or copies it raw when tty is set), while forwarding
+// streams.InputStream into conn until the connection closes, the context is
+// cancelled, or the detach key sequence is read from stdin.
+func streamHijackedSession(ctx context.Context, conn net.Conn, tty bool, streams *entities.Streams, detachKeys []byte) error {
+	readDone := make(chan error, 1)
+	go func() {
+		if tty {
+			_, err := io.Copy(streams.OutputStream, conn)
+			readDone <- err
+			return
+		}
+		for {
+			eof, err := demuxFrame(conn, streams.OutputStream, streams.ErrorStream)
+			if err != nil || eof {
+				readDone <- err
+				return
+			}
+		}
+	}()
+
+	detached := make(chan error, 1)
+	if streams.InputStream != nil && streams.AttachInput {
+		go func() {
+			in := newDetachKeysReader(streams.InputStream, detachKeys)
+			_, err := io.Copy(conn, in)
+			if errors.Cause(err) == errDetached {
+				detached <- errDetached
+				return
+			}
+			if err != nil {
+				logrus.Errorf("Failed to forward input stream: %v", err)
+			}
+			// Half-close so the server sees EOF on stdin without us
+			// tearing down the read side of the demux goroutine above.
+			if half, ok := conn.(interface{ CloseWrite() error }); ok {
+				_ = half.CloseWrite()
+			}
+		}()
+	}
+
+	select {
+	case <-ctx.Done():
+		conn.Close()
+		return ctx.Err()
+	case err := <-readDone:
+		conn.Close()
+		return err
+	case err := <-detached:
+		// The user asked to detach: close the connection for real so the
+		// blocked read goroutine above unwinds too, and return right away
+		// without waiting on (or signalling) the remote process.
+		conn.Close()
+		return err
+	}
+}
+
+// watchResize forwards SIGWINCH to the given resize function until ctx is done.
+func watchResize(ctx context.Context, resize func(height, width uint) error) {
+	ch := make(chan os.Signal, 1)
+	notifyWinch(ch)
+	defer signal.Stop(ch)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ch:
+			h, w, err := getTerminalSize()
+			if err != nil {
+				logrus.Debugf("Failed to read terminal size for resize: %v", err)
+				continue
+			}
+			if err := resize(h, w); err != nil {
+				logrus.Debugf("Failed to resize remote tty: %v", err)
+			}
+		}
+	}
+}
+
+func (ic *ContainerEngine) ContainerAttach(ctx context.Context, nameOrId string, options entities.AttachOptions) error {
+	ctrs, err := getContainersByContext(ic.ClientCxt, false, []string{nameOrId})
+	if err != nil {
+		return err
+	}
+	ctr := ctrs[0]
+
+	data, err := containers.Inspect(ic.ClientCxt, ctr.ID, nil)
+	if err != nil {
+		return err
+	}
+
+	conn, err := containers.Attach(ic.ClientCxt, ctr.ID, options.Stdin != nil, true, options.Recent)
+	if err != nil {
+		return err
+	}
+
+	attachCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if data.Config.Tty {
+		go watchResize(attachCtx, func(height, width uint) error {
+			return containers.ResizeContainerTTY(ic.ClientCxt, ctr.ID, height, width)
+		})
+	}
+
+	err = streamHijackedSession(attachCtx, conn, data.Config.Tty, options.Streams, []byte(options.DetachKeys))
+	if err != nil && errors.Cause(err) == errDetached {
+		return nil
+	}
+	return err
+}
+
+// startAttachedContainer hijacks an attach connection to ctr, issues the
+// start request once the connection is established so that no output is
+// lost between the two, and streams the result until the container exits
+// or the caller detaches.
+func (ic *ContainerEngine) startAttachedContainer(ctx context.Context, ctr entities.ListContainer, options entities.ContainerStartOptions) error {
+	data, err := containers.Inspect(ic.ClientCxt, ctr.ID, nil)
+	if err != nil {
+		return err
+	}
+
+	conn, err := containers.Attach(ic.ClientCxt, ctr.ID, true, true, false)
+	if err != nil {
+		return err
+	}
+
+	if err := containers.Start(ic.ClientCxt, ctr.ID, &options.DetachKeys); err != nil {
+		conn.Close()
+		return err
+	}
+
+	attachCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if data.Config.Tty {
+		go watchResize(attachCtx, func(height, width uint) error {
+			return containers.ResizeContainerTTY(ic.ClientCxt, ctr.ID, height, width)
+		})
+	}
+
+	err = streamHijackedSession(attachCtx, conn, data.Config.Tty, options.Streams, []byte(options.DetachKeys))
+	if err != nil && errors.Cause(err) == errDetached {
+		return nil
+	}
+	return err
+}
+
+func (ic *ContainerEngine) ContainerExec(ctx context.Context, nameOrId string, options entities.ExecOptions) (int, error) {
+	ctrs, err := getContainersByContext(ic.ClientCxt, false, []string{nameOrId})
+	if err != nil {
+		return 125, err
+	}
+	ctr := ctrs[0]
+
+	execId, err := containers.ExecCreate(ic.ClientCxt, ctr.ID, &options)
+	if err != nil {
+		return 125, err
+	}
+
+	if options.Detach {
+		if err := containers.ExecStartDetached(ic.ClientCxt, execId); err != nil {
+			return 125, err
+		}
+		return 0, nil
+	}
+
+	conn, err := containers.ExecStartAndAttach(ic.ClientCxt, execId, options.Tty)
+	if err != nil {
+		return 125, err
+	}
+
+	execCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if options.Tty {
+		go watchResize(execCtx, func(height, width uint) error {
+			return containers.ResizeExecTTY(ic.ClientCxt, execId, height, width)
+		})
+	}
+
+	err = streamHijackedSession(execCtx, conn, options.Tty, options.Streams, []byte(options.DetachKeys))
+	if err != nil && errors.Cause(err) != errDetached {
+		return 125, err
+	}
+
+	inspectOut, err := containers.ExecInspect(ic.ClientCxt, execId)
+	if err != nil {
+		return 125, err
+	}
+	return inspectOut.ExitCode, nil
+}
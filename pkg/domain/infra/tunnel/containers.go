@@ -2,8 +2,12 @@ package tunnel
 
 import (
 	"context"
+	"encoding/json"
 	"io"
+	"io/ioutil"
 	"os"
+	"strings"
+	"sync"
 
 	"github.com/containers/common/pkg/config"
 	"github.com/containers/image/v5/docker/reference"
@@ -12,8 +16,16 @@ import (
 	"github.com/containers/libpod/pkg/domain/entities"
 	"github.com/containers/libpod/pkg/specgen"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 )
 
+// isInvalidStateErr reports whether err is the remote equivalent of
+// define.ErrCtrStateInvalid. Errors returned over the API are plain
+// strings, so it is matched by substring rather than errors.Is.
+func isInvalidStateErr(err error) bool {
+	return err != nil && strings.Contains(err.Error(), define.ErrCtrStateInvalid.Error())
+}
+
 func (ic *ContainerEngine) ContainerExists(ctx context.Context, nameOrId string) (*entities.BoolReport, error) {
 	exists, err := containers.Exists(ic.ClientCxt, nameOrId)
 	return &entities.BoolReport{Value: exists}, err
@@ -50,6 +62,10 @@ func (ic *ContainerEngine) ContainerPause(ctx context.Context, namesOrIds []stri
 	}
 	for _, c := range ctrs {
 		err := containers.Pause(ic.ClientCxt, c.ID)
+		if options.All && isInvalidStateErr(err) {
+			logrus.Debugf("Container %s is not running, skipping pause", c.ID)
+			continue
+		}
 		reports = append(reports, &entities.PauseUnpauseReport{Id: c.ID, Err: err})
 	}
 	return reports, nil
@@ -65,6 +81,10 @@ func (ic *ContainerEngine) ContainerUnpause(ctx context.Context, namesOrIds []st
 	}
 	for _, c := range ctrs {
 		err := containers.Unpause(ic.ClientCxt, c.ID)
+		if options.All && isInvalidStateErr(err) {
+			logrus.Debugf("Container %s is not paused, skipping unpause", c.ID)
+			continue
+		}
 		reports = append(reports, &entities.PauseUnpauseReport{Id: c.ID, Err: err})
 	}
 	return reports, nil
@@ -81,6 +101,10 @@ func (ic *ContainerEngine) ContainerStop(ctx context.Context, namesOrIds []strin
 	for _, c := range ctrs {
 		report := entities.StopReport{Id: c.ID}
 		report.Err = containers.Stop(ic.ClientCxt, c.ID, &options.Timeout)
+		if options.All && isInvalidStateErr(report.Err) {
+			logrus.Debugf("Container %s is not running, skipping stop", c.ID)
+			continue
+		}
 		// TODO we need to associate errors returned by http with common
 		// define.errors so that we can equity tests. this will allow output
 		// to be the same as the native client
@@ -98,10 +122,12 @@ func (ic *ContainerEngine) ContainerKill(ctx context.Context, namesOrIds []strin
 		return nil, err
 	}
 	for _, c := range ctrs {
-		reports = append(reports, &entities.KillReport{
-			Id:  c.ID,
-			Err: containers.Kill(ic.ClientCxt, c.ID, options.Signal),
-		})
+		err := containers.Kill(ic.ClientCxt, c.ID, options.Signal)
+		if options.All && isInvalidStateErr(err) {
+			logrus.Debugf("Container %s is not running, skipping kill", c.ID)
+			continue
+		}
+		reports = append(reports, &entities.KillReport{Id: c.ID, Err: err})
 	}
 	return reports, nil
 }
@@ -120,10 +146,12 @@ func (ic *ContainerEngine) ContainerRestart(ctx context.Context, namesOrIds []st
 		return nil, err
 	}
 	for _, c := range ctrs {
-		reports = append(reports, &entities.RestartReport{
-			Id:  c.ID,
-			Err: containers.Restart(ic.ClientCxt, c.ID, timeout),
-		})
+		err := containers.Restart(ic.ClientCxt, c.ID, timeout)
+		if options.All && isInvalidStateErr(err) {
+			logrus.Debugf("Container %s is not running, skipping restart", c.ID)
+			continue
+		}
+		reports = append(reports, &entities.RestartReport{Id: c.ID, Err: err})
 	}
 	return reports, nil
 }
@@ -233,6 +261,13 @@ func (ic *ContainerEngine) ContainerExport(ctx context.Context, nameOrId string,
 	return containers.Export(ic.ClientCxt, nameOrId, w)
 }
 
+// ContainerCheckpoint dumps the given containers' state to disk. When
+// options.PreCheckpoint is set, only memory pages are dumped while the
+// container keeps running; a chain of such passes, each pointed at the
+// last via options.Parent/options.WithPrevious, lets the final (non-pre)
+// checkpoint capture only the pages dirtied since the last pass, reducing
+// downtime. options.Export still produces a single importable tarball on
+// the final pass, with the server bundling the parent chain into it.
 func (ic *ContainerEngine) ContainerCheckpoint(ctx context.Context, namesOrIds []string, options entities.CheckpointOptions) ([]*entities.CheckpointReport, error) {
 	var (
 		reports []*entities.CheckpointReport
@@ -240,6 +275,13 @@ func (ic *ContainerEngine) ContainerCheckpoint(ctx context.Context, namesOrIds [
 		ctrs    []entities.ListContainer
 	)
 
+	// Parent is a path on the remote podman host running the checkpoint,
+	// not on this client, so it can't be stat'd locally: the server
+	// validates the parent chain and returns its error if it's missing.
+	if options.WithPrevious && options.Parent == "" {
+		return nil, errors.New("WithPrevious requires Parent to point at the directory of the prior pre-checkpoint")
+	}
+
 	if options.All {
 		allCtrs, err := getContainersByContext(ic.ClientCxt, true, []string{})
 		if err != nil {
@@ -259,7 +301,7 @@ func (ic *ContainerEngine) ContainerCheckpoint(ctx context.Context, namesOrIds [
 		}
 	}
 	for _, c := range ctrs {
-		report, err := containers.Checkpoint(ic.ClientCxt, c.ID, &options.Keep, &options.LeaveRuninng, &options.TCPEstablished, &options.IgnoreRootFS, &options.Export)
+		report, err := containers.Checkpoint(ic.ClientCxt, c.ID, &options.Keep, &options.LeaveRuninng, &options.TCPEstablished, &options.IgnoreRootFS, &options.Export, &options.PreCheckpoint, &options.WithPrevious, &options.Parent)
 		if err != nil {
 			reports = append(reports, &entities.CheckpointReport{Id: c.ID, Err: err})
 		}
@@ -310,31 +352,177 @@ func (ic *ContainerEngine) ContainerCreate(ctx context.Context, s *specgen.SpecG
 	return &entities.ContainerCreateReport{Id: response.ID}, nil
 }
 
-func (ic *ContainerEngine) ContainerLogs(ctx context.Context, containers []string, options entities.ContainerLogsOptions) error {
-	// The endpoint is not ready yet and requires some more work.
-	return errors.New("not implemented yet")
+func (ic *ContainerEngine) ContainerLogs(ctx context.Context, namesOrIds []string, options entities.ContainerLogsOptions) error {
+	tagged := len(namesOrIds) > 1
+
+	errChan := make(chan error, len(namesOrIds))
+	var wg sync.WaitGroup
+	wg.Add(len(namesOrIds))
+	for _, nameOrId := range namesOrIds {
+		nameOrId := nameOrId
+		go func() {
+			defer wg.Done()
+			if err := ic.containerLogs(ctx, nameOrId, options, tagged); err != nil {
+				errChan <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errChan)
+
+	var err error
+	for e := range errChan {
+		switch {
+		case err == nil:
+			err = e
+		default:
+			logrus.Errorf("Error logging container: %v", e)
+		}
+	}
+	return err
 }
 
-func (ic *ContainerEngine) ContainerAttach(ctx context.Context, nameOrId string, options entities.AttachOptions) error {
-	return errors.New("not implemented")
+// containerLogs streams logs for a single container, demuxing stdout and
+// stderr when the container was created without a TTY and tagging each
+// line with nameOrId when tagged is set.
+func (ic *ContainerEngine) containerLogs(ctx context.Context, nameOrId string, options entities.ContainerLogsOptions, tagged bool) error {
+	data, err := containers.Inspect(ic.ClientCxt, nameOrId, nil)
+	if err != nil {
+		return err
+	}
+
+	body, err := containers.Logs(ic.ClientCxt, nameOrId, options)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	// Closing body unblocks whichever read is in progress below, so a
+	// cancelled ctx interrupts a stalled Follow stream right away instead
+	// of only being noticed between completed frames.
+	closed := make(chan struct{})
+	defer close(closed)
+	go func() {
+		select {
+		case <-ctx.Done():
+			body.Close()
+		case <-closed:
+		}
+	}()
+
+	tag := ""
+	if tagged {
+		tag = nameOrId + " | "
+	}
+
+	var stdout, stderr io.Writer = ioutil.Discard, ioutil.Discard
+	if options.Stdout {
+		stdout = newTaggedWriter(options.Writer, tag)
+	}
+	if options.Stderr {
+		stderr = newTaggedWriter(options.Writer, tag)
+	}
+
+	if data.Config.Tty {
+		_, err := io.Copy(stdout, body)
+		if err != nil && err != io.EOF {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		return nil
+	}
+
+	for {
+		eof, err := demuxFrame(body, stdout, stderr)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		if eof {
+			return nil
+		}
+	}
 }
 
-func (ic *ContainerEngine) ContainerExec(ctx context.Context, nameOrId string, options entities.ExecOptions) (int, error) {
-	return 125, errors.New("not implemented")
+// ContainerStats streams resource usage statistics for the given
+// containers, fanning each one's reports into a single channel tagged by
+// container ID. The channel is closed once every watched container has
+// stopped reporting (either it exited or, in one-shot mode, reported
+// once) or ctx is cancelled.
+func (ic *ContainerEngine) ContainerStats(ctx context.Context, namesOrIds []string, options entities.ContainerStatsOptions) (<-chan entities.ContainerStatsReport, error) {
+	ctrs, err := getContainersByContext(ic.ClientCxt, false, namesOrIds)
+	if err != nil {
+		return nil, err
+	}
+
+	reportChan := make(chan entities.ContainerStatsReport, len(ctrs))
+	var wg sync.WaitGroup
+	wg.Add(len(ctrs))
+	for _, c := range ctrs {
+		c := c
+		go func() {
+			defer wg.Done()
+			if err := ic.containerStats(ctx, c.ID, options.Stream, reportChan); err != nil {
+				reportChan <- entities.ContainerStatsReport{Error: err}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(reportChan)
+	}()
+	return reportChan, nil
 }
 
-func (ic *ContainerEngine) ContainerStart(ctx context.Context, namesOrIds []string, options entities.ContainerStartOptions) ([]*entities.ContainerStartReport, error) {
-	return nil, errors.New("not implemented")
+// containerStats streams newline-delimited JSON stats frames for a single
+// container, emitting one report per frame until the container stops
+// reporting (stream == false) or the connection is closed by the server
+// when the container exits.
+func (ic *ContainerEngine) containerStats(ctx context.Context, id string, stream bool, reportChan chan entities.ContainerStatsReport) error {
+	body, err := containers.Stats(ic.ClientCxt, id, &stream)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	// Closing body unblocks a decode in progress below, so a cancelled ctx
+	// interrupts a stalled streaming read right away instead of only being
+	// noticed between completed frames.
+	closed := make(chan struct{})
+	defer close(closed)
+	go func() {
+		select {
+		case <-ctx.Done():
+			body.Close()
+		case <-closed:
+		}
+	}()
+
+	dec := json.NewDecoder(body)
+	for {
+		var stats define.ContainerStats
+		if err := dec.Decode(&stats); err != nil {
+			if err == io.EOF || ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		stats.ContainerID = id
+		reportChan <- entities.ContainerStatsReport{Stats: []define.ContainerStats{stats}}
+		if !stream {
+			return nil
+		}
+	}
 }
 
 func (ic *ContainerEngine) ContainerList(ctx context.Context, options entities.ContainerListOptions) ([]entities.ListContainer, error) {
 	return containers.List(ic.ClientCxt, options.Filters, &options.All, &options.Last, &options.Pod, &options.Size, &options.Sync)
 }
 
-func (ic *ContainerEngine) ContainerRun(ctx context.Context, opts entities.ContainerRunOptions) (*entities.ContainerRunReport, error) {
-	return nil, errors.New("not implemented")
-}
-
 func (ic *ContainerEngine) ContainerDiff(ctx context.Context, nameOrId string, _ entities.DiffOptions) (*entities.DiffReport, error) {
 	changes, err := containers.Diff(ic.ClientCxt, nameOrId)
 	return &entities.DiffReport{Changes: changes}, err
@@ -376,10 +564,6 @@ func (ic *ContainerEngine) ContainerPort(ctx context.Context, nameOrId string, o
 	return nil, errors.New("not implemented")
 }
 
-func (ic *ContainerEngine) ContainerCp(ctx context.Context, source, dest string, options entities.ContainerCpOptions) (*entities.ContainerCpReport, error) {
-	return nil, errors.New("not implemented")
-}
-
 // Shutdown Libpod engine
 func (ic *ContainerEngine) Shutdown(_ context.Context) {
 }
@@ -0,0 +1,27 @@
+package tunnel
+
+import (
+	"testing"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/pkg/errors"
+)
+
+func TestIsInvalidStateErr(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil error", nil, false},
+		{"exact invalid state error", define.ErrCtrStateInvalid, true},
+		{"wrapped invalid state error", errors.Wrap(define.ErrCtrStateInvalid, "unpause"), true},
+		{"error surfaced as a plain string over the wire", errors.New(define.ErrCtrStateInvalid.Error()), true},
+		{"unrelated error", errors.New("no such container"), false},
+	}
+	for _, tt := range tests {
+		if got := isInvalidStateErr(tt.err); got != tt.want {
+			t.Errorf("%s: isInvalidStateErr() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
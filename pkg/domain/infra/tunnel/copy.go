@@ -0,0 +1,286 @@
+package tunnel
+
+import (
+	"archive/tar"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/containers/libpod/pkg/bindings/containers"
+	"github.com/containers/libpod/pkg/domain/entities"
+	"github.com/pkg/errors"
+)
+
+// splitCpArg splits a `podman cp` SOURCE or DEST argument of the form
+// [container:]path into its container (empty for a local path) and path
+// components.
+func splitCpArg(arg string) (ctr, path string) {
+	if i := strings.Index(arg, ":"); i > 0 {
+		return arg[:i], arg[i+1:]
+	}
+	return "", arg
+}
+
+func (ic *ContainerEngine) ContainerCp(ctx context.Context, source, dest string, options entities.ContainerCpOptions) (*entities.ContainerCpReport, error) {
+	srcCtr, srcPath := splitCpArg(source)
+	dstCtr, dstPath := splitCpArg(dest)
+
+	switch {
+	case srcCtr != "" && dstCtr != "":
+		return nil, errors.New("copying between two containers is not supported")
+	case srcCtr == "" && dstCtr == "":
+		return nil, errors.New("one of source or destination must be a container path")
+	case srcCtr != "":
+		return ic.copyFromContainer(srcCtr, srcPath, dstPath, options)
+	default:
+		return ic.copyToContainer(dstCtr, dstPath, srcPath, options)
+	}
+}
+
+// copyFromContainer implements the GET (copy-out) half of `podman cp`.
+func (ic *ContainerEngine) copyFromContainer(ctrID, ctrPath, hostPath string, options entities.ContainerCpOptions) (*entities.ContainerCpReport, error) {
+	srcStat, err := containers.StatArchive(ic.ClientCxt, ctrID, ctrPath)
+	if err != nil {
+		return nil, err
+	}
+
+	destDir := hostPath
+	renameTo := ""
+
+	if hostInfo, statErr := os.Stat(hostPath); statErr == nil {
+		switch {
+		case hostInfo.IsDir():
+			// dir-into-dir: `src/.` copies src's contents directly into
+			// hostPath, while plain `src` nests them under hostPath/src.
+			if !strings.HasSuffix(ctrPath, "/.") {
+				renameTo = filepath.Base(ctrPath)
+			}
+		case srcStat.IsDir():
+			return nil, errors.Errorf("cannot copy directory %q onto existing file %q", ctrPath, hostPath)
+		case options.NoOverwriteDirNonDir:
+			return nil, errors.Errorf("%q already exists", hostPath)
+		}
+	} else if strings.HasSuffix(ctrPath, "/.") {
+		// hostPath becomes a fresh directory holding src's contents.
+		if err := os.MkdirAll(hostPath, 0o755); err != nil {
+			return nil, err
+		}
+	} else {
+		// hostPath doesn't exist yet: it becomes the copied entry itself
+		// (a file renamed to hostPath, or a directory renamed to hostPath),
+		// so extract one level up and rename the top-level entry in place.
+		destDir = filepath.Dir(hostPath)
+		renameTo = filepath.Base(hostPath)
+		mkdirTarget := destDir
+		if srcStat.IsDir() {
+			mkdirTarget = hostPath
+		}
+		if err := os.MkdirAll(mkdirTarget, 0o755); err != nil {
+			return nil, err
+		}
+	}
+
+	body, err := containers.CopyFromArchive(ic.ClientCxt, ctrID, ctrPath)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	n, err := extractTar(body, destDir, renameTo)
+	if err != nil {
+		return nil, err
+	}
+	return &entities.ContainerCpReport{Size: n}, nil
+}
+
+// copyToContainer implements the PUT (copy-in) half of `podman cp`.
+func (ic *ContainerEngine) copyToContainer(ctrID, ctrPath, hostPath string, options entities.ContainerCpOptions) (*entities.ContainerCpReport, error) {
+	hostInfo, err := os.Stat(hostPath)
+	if err != nil {
+		return nil, err
+	}
+
+	dstPath := ctrPath
+	if dstStat, err := containers.StatArchive(ic.ClientCxt, ctrID, ctrPath); err == nil {
+		switch {
+		case dstStat.IsDir():
+			dstPath = filepath.Join(ctrPath, filepath.Base(hostPath))
+		case options.NoOverwriteDirNonDir && hostInfo.IsDir():
+			return nil, errors.Errorf("cannot copy directory %q onto existing file %q", hostPath, ctrPath)
+		}
+	}
+
+	tr, err := createTar(hostPath, options.Chown)
+	if err != nil {
+		return nil, err
+	}
+	defer tr.Close()
+
+	counter := &countingReader{r: tr}
+	if err := containers.CopyToArchive(ic.ClientCxt, ctrID, dstPath, counter); err != nil {
+		return nil, err
+	}
+	return &entities.ContainerCpReport{Size: counter.n}, nil
+}
+
+// withinDir reports whether target is base itself or a descendant of it,
+// after cleaning both paths. It is used to reject tar entries and symlink
+// targets that would otherwise extract outside the destination directory
+// (e.g. via a "../" entry name).
+func withinDir(base, target string) bool {
+	cleanBase := filepath.Clean(base)
+	cleanTarget := filepath.Clean(target)
+	if cleanTarget == cleanBase {
+		return true
+	}
+	return strings.HasPrefix(cleanTarget, cleanBase+string(os.PathSeparator))
+}
+
+// extractTar reads a tar stream returned by the archive endpoint and
+// writes its entries beneath destDir. When renameTo is non-empty, the
+// top-level path component of every entry is rewritten to renameTo, so
+// copying `ctr:src` to a not-yet-existing `dest` lays src's contents
+// under dest's own name rather than under src's. An empty renameTo keeps
+// entry names as they arrive.
+func extractTar(r io.Reader, destDir, renameTo string) (int64, error) {
+	tr := tar.NewReader(r)
+	var total int64
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return total, nil
+		}
+		if err != nil {
+			return total, err
+		}
+
+		name := hdr.Name
+		if renameTo != "" {
+			if parts := strings.SplitN(name, "/", 2); len(parts) == 2 {
+				name = filepath.Join(renameTo, parts[1])
+			} else {
+				name = renameTo
+			}
+		}
+		target := filepath.Join(destDir, name)
+		if !withinDir(destDir, target) {
+			return total, errors.Errorf("tar entry %q escapes destination directory %q", hdr.Name, destDir)
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, os.FileMode(hdr.Mode)); err != nil {
+				return total, err
+			}
+		case tar.TypeSymlink:
+			linkTarget := hdr.Linkname
+			if !filepath.IsAbs(linkTarget) {
+				linkTarget = filepath.Join(filepath.Dir(target), linkTarget)
+			}
+			if !withinDir(destDir, linkTarget) {
+				return total, errors.Errorf("symlink %q targets %q outside destination directory %q", hdr.Name, hdr.Linkname, destDir)
+			}
+			_ = os.Remove(target)
+			if err := os.Symlink(hdr.Linkname, target); err != nil {
+				return total, err
+			}
+		default:
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return total, err
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, os.FileMode(hdr.Mode))
+			if err != nil {
+				return total, err
+			}
+			n, cerr := io.Copy(f, tr)
+			f.Close()
+			total += n
+			if cerr != nil {
+				return total, cerr
+			}
+		}
+	}
+}
+
+// createTar builds a tar stream of srcPath (file or directory) suitable
+// for the archive PUT endpoint. chown, when non-empty ("uid[:gid]"),
+// rewrites the ownership of every entry to the given numeric ids.
+func createTar(srcPath, chown string) (io.ReadCloser, error) {
+	uid, gid := -1, -1
+	if chown != "" {
+		parts := strings.SplitN(chown, ":", 2)
+		if u, err := strconv.Atoi(parts[0]); err == nil {
+			uid = u
+		}
+		if len(parts) == 2 {
+			if g, err := strconv.Atoi(parts[1]); err == nil {
+				gid = g
+			}
+		}
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		tw := tar.NewWriter(pw)
+		err := filepath.Walk(srcPath, func(path string, fi os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			link := ""
+			if fi.Mode()&os.ModeSymlink != 0 {
+				if link, err = os.Readlink(path); err != nil {
+					return err
+				}
+			}
+			hdr, err := tar.FileInfoHeader(fi, link)
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(filepath.Dir(srcPath), path)
+			if err != nil {
+				return err
+			}
+			hdr.Name = filepath.ToSlash(rel)
+			if uid >= 0 {
+				hdr.Uid = uid
+			}
+			if gid >= 0 {
+				hdr.Gid = gid
+			}
+			if err := tw.WriteHeader(hdr); err != nil {
+				return err
+			}
+			if fi.Mode().IsRegular() {
+				f, err := os.Open(path)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				_, err = io.Copy(tw, f)
+				return err
+			}
+			return nil
+		})
+		if err == nil {
+			err = tw.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	return pr, nil
+}
+
+// countingReader wraps r, tallying the number of bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,206 @@
+package tunnel
+
+import (
+	"archive/tar"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSplitCpArg(t *testing.T) {
+	tests := []struct {
+		arg      string
+		wantCtr  string
+		wantPath string
+	}{
+		{"mycontainer:/etc/passwd", "mycontainer", "/etc/passwd"},
+		{"/local/path", "", "/local/path"},
+		{"./relative/path", "", "./relative/path"},
+		{":leading-colon", "", ":leading-colon"},
+	}
+	for _, tt := range tests {
+		ctr, path := splitCpArg(tt.arg)
+		if ctr != tt.wantCtr || path != tt.wantPath {
+			t.Errorf("splitCpArg(%q) = (%q, %q), want (%q, %q)", tt.arg, ctr, path, tt.wantCtr, tt.wantPath)
+		}
+	}
+}
+
+func TestWithinDir(t *testing.T) {
+	tests := []struct {
+		base, target string
+		want         bool
+	}{
+		{"/dest", "/dest", true},
+		{"/dest", "/dest/file", true},
+		{"/dest", "/dest/../file", false},
+		{"/dest", "/destevil", false},
+		{"/dest", "/other", false},
+	}
+	for _, tt := range tests {
+		if got := withinDir(tt.base, tt.target); got != tt.want {
+			t.Errorf("withinDir(%q, %q) = %v, want %v", tt.base, tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestExtractTarRenameTopLevel(t *testing.T) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	files := []struct {
+		name string
+		body string
+	}{
+		{"src/file.txt", "hello"},
+		{"src/sub/nested.txt", "world"},
+	}
+	for _, f := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: f.name, Mode: 0o644, Size: int64(len(f.body))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(f.body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	destDir, err := ioutil.TempDir("", "extract-tar-rename")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(destDir)
+
+	if _, err := extractTar(bytes.NewReader(buf.Bytes()), destDir, "src"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(filepath.Join(destDir, "src", "file.txt")); err != nil {
+		t.Errorf("expected renamed entry under src/, got: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(destDir, "src", "sub", "nested.txt")); err != nil {
+		t.Errorf("expected renamed nested entry under src/, got: %v", err)
+	}
+}
+
+// TestExtractTarRenameForNewDestination covers the `copyFromContainer`
+// "destination doesn't exist yet" case: copying `ctr:confdir` to a fresh
+// `./newdir` must rename the top-level entry to newdir's own basename
+// rather than nesting it as newdir/confdir/...
+func TestExtractTarRenameForNewDestination(t *testing.T) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	files := []struct {
+		name string
+		body string
+	}{
+		{"confdir/a.conf", "a"},
+		{"confdir/sub/b.conf", "b"},
+	}
+	for _, f := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: f.name, Mode: 0o644, Size: int64(len(f.body))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(f.body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	parent, err := ioutil.TempDir("", "extract-tar-new-dest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(parent)
+
+	// Mirrors copyFromContainer's handling of a not-yet-existing
+	// hostPath: destDir is one level up, renameTo is hostPath's own
+	// basename ("newdir"), not the source's ("confdir").
+	if _, err := extractTar(bytes.NewReader(buf.Bytes()), parent, "newdir"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(filepath.Join(parent, "newdir", "a.conf")); err != nil {
+		t.Errorf("expected newdir/a.conf, got: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(parent, "newdir", "sub", "b.conf")); err != nil {
+		t.Errorf("expected newdir/sub/b.conf, got: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(parent, "confdir")); err == nil {
+		t.Errorf("did not expect the source's own name (confdir) to survive the rename")
+	}
+}
+
+func TestExtractTarNoRenameForDotCopy(t *testing.T) {
+	// For `ctr:src/.` the server already strips the "src" directory
+	// component, so the archive arrives with flat entry names rather
+	// than nested under "src/".
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	files := []struct {
+		name string
+		body string
+	}{
+		{"file.txt", "hello"},
+		{"sub/nested.txt", "world"},
+	}
+	for _, f := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: f.name, Mode: 0o644, Size: int64(len(f.body))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(f.body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	destDir, err := ioutil.TempDir("", "extract-tar-no-rename")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(destDir)
+
+	// An empty renameTo mirrors `cp ctr:src/. dest/`: contents land
+	// directly under destDir, with no wrapping "src" directory.
+	if _, err := extractTar(bytes.NewReader(buf.Bytes()), destDir, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(filepath.Join(destDir, "file.txt")); err != nil {
+		t.Errorf("expected file.txt directly under destDir, got: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(destDir, "sub", "nested.txt")); err != nil {
+		t.Errorf("expected sub/nested.txt directly under destDir, got: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(destDir, "src")); err == nil {
+		t.Errorf("did not expect a wrapping src/ directory to be created")
+	}
+}
+
+func TestExtractTarRejectsPathEscape(t *testing.T) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	if err := tw.WriteHeader(&tar.Header{Name: "../escape.txt", Mode: 0o644, Size: 0}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	destDir, err := ioutil.TempDir("", "extract-tar-escape")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(destDir)
+
+	if _, err := extractTar(bytes.NewReader(buf.Bytes()), destDir, ""); err == nil {
+		t.Fatal("expected extractTar to reject a tar entry escaping destDir, got nil error")
+	}
+}
@@ -0,0 +1,25 @@
+// +build !windows
+
+package tunnel
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/containers/libpod/pkg/terminal"
+)
+
+// notifyWinch arranges for SIGWINCH (terminal resize) to be delivered on ch.
+func notifyWinch(ch chan os.Signal) {
+	signal.Notify(ch, syscall.SIGWINCH)
+}
+
+// getTerminalSize returns the current size of the attached stdin terminal.
+func getTerminalSize() (height, width uint, err error) {
+	size, err := terminal.GetSize(int(os.Stdin.Fd()))
+	if err != nil {
+		return 0, 0, err
+	}
+	return uint(size.Height), uint(size.Width), nil
+}
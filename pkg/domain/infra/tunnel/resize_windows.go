@@ -0,0 +1,20 @@
+// +build windows
+
+package tunnel
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// notifyWinch is a no-op on Windows: there is no SIGWINCH, so terminal
+// resize events are simply never delivered to remote sessions.
+func notifyWinch(ch chan os.Signal) {}
+
+// getTerminalSize is unsupported on Windows.
+func getTerminalSize() (height, width uint, err error) {
+	return 0, 0, errNotSupported
+}
+
+var errNotSupported = errors.New("resizing the terminal is not supported on Windows")
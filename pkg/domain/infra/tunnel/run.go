@@ -0,0 +1,119 @@
+package tunnel
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/containers/libpod/pkg/bindings/containers"
+	"github.com/containers/libpod/pkg/bindings/pods"
+	"github.com/containers/libpod/pkg/domain/entities"
+	"github.com/sirupsen/logrus"
+)
+
+func (ic *ContainerEngine) ContainerStart(ctx context.Context, namesOrIds []string, options entities.ContainerStartOptions) ([]*entities.ContainerStartReport, error) {
+	var reports []*entities.ContainerStartReport
+	ctrs, err := getContainersByContext(ic.ClientCxt, false, namesOrIds)
+	if err != nil {
+		return nil, err
+	}
+
+	startedPods := map[string]bool{}
+	for _, ctr := range ctrs {
+		report := &entities.ContainerStartReport{Id: ctr.ID}
+
+		if options.Recursive && ctr.Pod != "" && !startedPods[ctr.Pod] {
+			if _, err := pods.Start(ic.ClientCxt, ctr.Pod); err != nil {
+				report.Err = err
+				reports = append(reports, report)
+				continue
+			}
+			startedPods[ctr.Pod] = true
+		}
+
+		if options.Attach {
+			report.Err = ic.startAttachedContainer(ctx, ctr, options)
+			reports = append(reports, report)
+			continue
+		}
+
+		report.Err = containers.Start(ic.ClientCxt, ctr.ID, &options.DetachKeys)
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+// proxySignals forwards every signal the client process receives, except
+// SIGCHLD, SIGURG and SIGWINCH, to ctrID via the kill endpoint, until ctx
+// is cancelled.
+func proxySignals(ctx context.Context, clientCxt context.Context, ctrID string) chan os.Signal {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case s := <-sigChan:
+				sysSig, ok := s.(syscall.Signal)
+				if !ok || sysSig == syscall.SIGCHLD || sysSig == syscall.SIGURG || sysSig == syscall.SIGWINCH {
+					continue
+				}
+				if err := containers.Kill(clientCxt, ctrID, sysSig.String()); err != nil {
+					logrus.Errorf("Failed to forward signal %s to container %s: %v", sysSig, ctrID, err)
+				}
+			}
+		}
+	}()
+	return sigChan
+}
+
+func (ic *ContainerEngine) ContainerRun(ctx context.Context, opts entities.ContainerRunOptions) (*entities.ContainerRunReport, error) {
+	con, err := containers.CreateWithSpec(ic.ClientCxt, opts.Spec)
+	if err != nil {
+		return nil, err
+	}
+	report := &entities.ContainerRunReport{Id: con.ID}
+
+	if opts.SigProxy {
+		sigChan := proxySignals(ctx, ic.ClientCxt, con.ID)
+		defer signal.Stop(sigChan)
+	}
+
+	ctr := entities.ListContainer{ID: con.ID}
+	startOptions := entities.ContainerStartOptions{
+		Attach:     opts.Attach,
+		DetachKeys: opts.DetachKeys,
+		Streams:    opts.Streams,
+	}
+
+	var startErr error
+	if opts.Attach {
+		startErr = ic.startAttachedContainer(ctx, ctr, startOptions)
+	} else {
+		startErr = containers.Start(ic.ClientCxt, con.ID, &opts.DetachKeys)
+	}
+	if startErr != nil {
+		return report, startErr
+	}
+
+	exitCode, err := containers.Wait(ic.ClientCxt, con.ID, nil)
+	if err != nil {
+		return report, err
+	}
+	report.ExitCode = int(exitCode)
+
+	if opts.Rm {
+		// The container has already exited by this point (containers.Wait
+		// above returned), so there's nothing left to wait on: remove it
+		// synchronously rather than risk the caller exiting before a
+		// backgrounded removal runs.
+		force, volumes := true, false
+		if err := containers.Remove(ic.ClientCxt, con.ID, &force, &volumes); err != nil {
+			logrus.Errorf("Failed to remove container %s: %v", con.ID, err)
+		}
+	}
+
+	return report, nil
+}
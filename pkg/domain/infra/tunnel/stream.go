@@ -0,0 +1,76 @@
+package tunnel
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// frameHeaderLen is the size, in bytes, of the multiplexed stream frame
+// header used by the logs, attach and exec endpoints when no TTY is
+// allocated: one byte identifying the stream (1 == stdout, 2 == stderr),
+// three reserved bytes, and a 4-byte big endian payload length.
+const frameHeaderLen = 8
+
+// demuxFrame reads a single multiplexed frame from src and copies its
+// payload to stdout or stderr according to the frame's stream byte. A nil
+// destination writer discards that frame's payload. eof is true when src
+// was exhausted cleanly at a frame boundary.
+func demuxFrame(src io.Reader, stdout, stderr io.Writer) (eof bool, err error) {
+	header := make([]byte, frameHeaderLen)
+	if _, err := io.ReadFull(src, header); err != nil {
+		if err == io.EOF {
+			return true, nil
+		}
+		return false, err
+	}
+	length := int64(binary.BigEndian.Uint32(header[4:]))
+	w := stdout
+	if header[0] == 2 {
+		w = stderr
+	}
+	if w == nil {
+		w = ioutil.Discard
+	}
+	if _, err := io.CopyN(w, src, length); err != nil {
+		return false, err
+	}
+	return false, nil
+}
+
+// taggedWriter prefixes every line written to it with tag, so output from
+// several containers can be fanned into a single stream (e.g. `podman
+// logs` against more than one container) without interleaved lines.
+type taggedWriter struct {
+	w   io.Writer
+	tag string
+}
+
+// newTaggedWriter wraps w so every line written to it is prefixed with
+// tag. An empty tag returns w unchanged.
+func newTaggedWriter(w io.Writer, tag string) io.Writer {
+	if tag == "" {
+		return w
+	}
+	return &taggedWriter{w: w, tag: tag}
+}
+
+func (t *taggedWriter) Write(p []byte) (int, error) {
+	total := len(p)
+	for len(p) > 0 {
+		idx := bytes.IndexByte(p, '\n')
+		if idx < 0 {
+			if _, err := fmt.Fprintf(t.w, "%s%s", t.tag, p); err != nil {
+				return 0, err
+			}
+			break
+		}
+		if _, err := fmt.Fprintf(t.w, "%s%s\n", t.tag, p[:idx]); err != nil {
+			return 0, err
+		}
+		p = p[idx+1:]
+	}
+	return total, nil
+}
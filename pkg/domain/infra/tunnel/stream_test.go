@@ -0,0 +1,92 @@
+package tunnel
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+)
+
+func frame(stream byte, payload string) []byte {
+	hdr := make([]byte, frameHeaderLen)
+	hdr[0] = stream
+	binary.BigEndian.PutUint32(hdr[4:], uint32(len(payload)))
+	return append(hdr, payload...)
+}
+
+func TestDemuxFrameRoutesByStream(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	src := bytes.NewBuffer(append(frame(1, "out"), frame(2, "err")...))
+
+	for i := 0; i < 2; i++ {
+		eof, err := demuxFrame(src, &stdout, &stderr)
+		if err != nil {
+			t.Fatalf("demuxFrame() error = %v", err)
+		}
+		if eof {
+			t.Fatalf("demuxFrame() reported eof early on frame %d", i)
+		}
+	}
+
+	if stdout.String() != "out" {
+		t.Errorf("stdout = %q, want %q", stdout.String(), "out")
+	}
+	if stderr.String() != "err" {
+		t.Errorf("stderr = %q, want %q", stderr.String(), "err")
+	}
+}
+
+func TestDemuxFrameEOF(t *testing.T) {
+	eof, err := demuxFrame(bytes.NewReader(nil), nil, nil)
+	if err != nil {
+		t.Fatalf("demuxFrame() error = %v", err)
+	}
+	if !eof {
+		t.Error("demuxFrame() on empty reader should report eof")
+	}
+}
+
+func TestDemuxFrameDiscardsNilWriter(t *testing.T) {
+	var stdout bytes.Buffer
+	src := bytes.NewBuffer(frame(2, "ignored"))
+
+	eof, err := demuxFrame(src, &stdout, nil)
+	if err != nil {
+		t.Fatalf("demuxFrame() error = %v", err)
+	}
+	if eof {
+		t.Fatal("demuxFrame() reported eof unexpectedly")
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("stdout should be untouched, got %q", stdout.String())
+	}
+}
+
+func TestDemuxFrameTruncatedHeader(t *testing.T) {
+	_, err := demuxFrame(bytes.NewReader([]byte{1, 0, 0}), nil, nil)
+	if err == nil || err == io.EOF {
+		t.Fatalf("demuxFrame() with truncated header error = %v, want a non-EOF error", err)
+	}
+}
+
+func TestTaggedWriterPrefixesEachLine(t *testing.T) {
+	var buf bytes.Buffer
+	w := newTaggedWriter(&buf, "ctr | ")
+
+	if _, err := w.Write([]byte("line one\nline two\npartial")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	want := "ctr | line one\nctr | line two\nctr | partial"
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestNewTaggedWriterEmptyTagIsNoop(t *testing.T) {
+	var buf bytes.Buffer
+	w := newTaggedWriter(&buf, "")
+	if w != io.Writer(&buf) {
+		t.Error("newTaggedWriter with an empty tag should return w unchanged")
+	}
+}